@@ -0,0 +1,282 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// IsStandardPackage reports whether importPath belongs to the Go standard
+// library, based on the usual convention that non-standard import paths
+// contain a dot in their first path element (e.g. a host name).
+func IsStandardPackage(importPath string) bool {
+	x := strings.SplitN(importPath, "/", 2)
+	return !strings.Contains(x[0], ".")
+}
+
+// CanonicalPackagePath strips a leading "vendor/" segment from importPath,
+// so that a package vendored at different depths (e.g. "vendor/example.com/foo"
+// vs. "a/vendor/example.com/foo") is recognized as the same package path
+// ("example.com/foo") rather than being counted once per vendor location.
+func CanonicalPackagePath(importPath string) string {
+	if i := strings.LastIndex(importPath, "vendor/"); i >= 0 {
+		if i == 0 || importPath[i-1] == '/' {
+			return importPath[i+len("vendor/"):]
+		}
+	}
+	return importPath
+}
+
+// Package describes a single Go package as resolved by a Loader.
+type Package struct {
+	// ImportPath is the path used to reach this package from whichever
+	// importer requested it first; it may still carry a "vendor/" prefix.
+	ImportPath string
+	// PackagePath is the canonical path used to key the dependency graph,
+	// so that two different vendored copies of the same package collapse
+	// into a single node.
+	PackagePath string
+	Dir         string
+	Standard    bool
+
+	// Imports maps each of this package's direct import paths (as written
+	// in its source) to the PackagePath they resolve to.
+	Imports map[string]string
+}
+
+type PackageCache struct {
+	mu       sync.Mutex
+	packages map[string]*Package
+}
+
+func NewPackageCache() *PackageCache {
+	return &PackageCache{
+		packages: make(map[string]*Package),
+	}
+}
+
+func (c *PackageCache) Get(packagePath string) (*Package, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	pkg, ok := c.packages[packagePath]
+	return pkg, ok
+}
+
+func (c *PackageCache) Put(pkg *Package) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.packages[pkg.PackagePath] = pkg
+}
+
+// PackageError records a failure to load a single package encountered
+// while walking the dependency graph, mirroring the shape of
+// golang.org/x/tools/go/packages.Package.Errors: the walk keeps going, and
+// the error is reported alongside the rest of the output instead of
+// aborting the whole run.
+type PackageError struct {
+	// ImportPath is the import, as written, that failed to resolve.
+	ImportPath string
+	// Dir is the importing package's directory.
+	Dir string
+	Err string
+}
+
+func (e *PackageError) Error() string {
+	return fmt.Sprintf("%s (imported from %s): %s", e.ImportPath, e.Dir, e.Err)
+}
+
+type DependencyManager struct {
+	loader   Loader
+	packages *PackageCache
+
+	// sem bounds the number of concurrent Loader.Load calls in flight.
+	sem chan struct{}
+	// loadGroup dedups concurrent Load calls for the same (path, srcDir).
+	loadGroup singleflight.Group
+	// nodeGroup dedups concurrent cache-check+recurse for the same
+	// PackagePath, even when it was reached via different (path, srcDir)
+	// pairs (e.g. two different vendor copies of the same package).
+	nodeGroup singleflight.Group
+
+	mu           sync.Mutex
+	dependencies map[string]map[string]struct{}
+
+	errMu      sync.Mutex
+	LoadErrors []PackageError
+}
+
+// NewDependencyManager returns a DependencyManager that loads packages
+// through loader, with at most concurrency Loader.Load calls in flight at
+// once. A concurrency of 0 or less defaults to runtime.GOMAXPROCS(0).
+func NewDependencyManager(loader Loader, concurrency int) *DependencyManager {
+	if concurrency <= 0 {
+		concurrency = runtime.GOMAXPROCS(0)
+	}
+	return &DependencyManager{
+		loader:       loader,
+		packages:     NewPackageCache(),
+		sem:          make(chan struct{}, concurrency),
+		dependencies: make(map[string]map[string]struct{}),
+	}
+}
+
+func (m *DependencyManager) Import(path string, srcDir string) (*Package, error) {
+	loadKey := srcDir + "\x00" + path
+	v, err, _ := m.loadGroup.Do(loadKey, func() (interface{}, error) {
+		m.sem <- struct{}{}
+		defer func() { <-m.sem }()
+		return m.loader.Load(path, srcDir)
+	})
+	if err != nil {
+		return nil, err
+	}
+	lr := v.(*LoadResult)
+
+	v, err, _ = m.nodeGroup.Do(lr.PackagePath, func() (interface{}, error) {
+		return m.buildNode(lr)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*Package), nil
+}
+
+// buildNode turns a freshly loaded LoadResult into a cached Package,
+// recursively importing its direct dependencies concurrently. It is only
+// ever run once per PackagePath, serialized through m.nodeGroup.
+func (m *DependencyManager) buildNode(lr *LoadResult) (*Package, error) {
+	if cachedPkg, ok := m.packages.Get(lr.PackagePath); ok {
+		return cachedPkg, nil
+	}
+
+	pkg := &Package{
+		ImportPath:  lr.ImportPath,
+		PackagePath: lr.PackagePath,
+		Dir:         lr.Dir,
+		Standard:    IsStandardPackage(lr.PackagePath),
+		Imports:     make(map[string]string, len(lr.Imports)),
+	}
+	m.packages.Put(pkg)
+
+	var wg sync.WaitGroup
+	var importsMu sync.Mutex
+	for _, im := range lr.Imports {
+		if im == "C" {
+			continue
+		}
+
+		im := im
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			importedPkg, err := m.Import(im, lr.Dir)
+			if err != nil {
+				m.recordError(im, lr.Dir, err)
+				return
+			}
+
+			importsMu.Lock()
+			pkg.Imports[im] = importedPkg.PackagePath
+			importsMu.Unlock()
+
+			m.mu.Lock()
+			if m.dependencies[pkg.PackagePath] == nil {
+				m.dependencies[pkg.PackagePath] = make(map[string]struct{})
+			}
+			m.dependencies[pkg.PackagePath][importedPkg.PackagePath] = struct{}{}
+			m.mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	return pkg, nil
+}
+
+func (m *DependencyManager) recordError(importPath, dir string, err error) {
+	m.errMu.Lock()
+	defer m.errMu.Unlock()
+	m.LoadErrors = append(m.LoadErrors, PackageError{ImportPath: importPath, Dir: dir, Err: err.Error()})
+}
+
+func (m *DependencyManager) Get(path string, srcDir string) (*Package, []string, error) {
+	pkg, err := m.Import(path, srcDir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	m.mu.Lock()
+	depsMap := m.dependencies[pkg.PackagePath]
+	var deps []string
+	if len(depsMap) > 0 {
+		deps = make([]string, 0, len(depsMap))
+		for im := range depsMap {
+			deps = append(deps, im)
+		}
+	}
+	m.mu.Unlock()
+
+	return pkg, deps, nil
+}
+
+// Neighbors returns the direct dependencies recorded for path, sorted.
+func (m *DependencyManager) Neighbors(path string) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	neighbors := make([]string, 0, len(m.dependencies[path]))
+	for to := range m.dependencies[path] {
+		neighbors = append(neighbors, to)
+	}
+	sort.Strings(neighbors)
+	return neighbors
+}
+
+// AllPackages returns every package resolved so far, in no particular order.
+func (m *DependencyManager) AllPackages() []*Package {
+	m.packages.mu.Lock()
+	defer m.packages.mu.Unlock()
+
+	pkgs := make([]*Package, 0, len(m.packages.packages))
+	for _, pkg := range m.packages.packages {
+		pkgs = append(pkgs, pkg)
+	}
+	return pkgs
+}
+
+func (m *DependencyManager) addDeps(deps map[string]struct{}, path string, keep func(string) bool) {
+	if !keep(path) {
+		return
+	}
+	if _, ok := deps[path]; ok {
+		return
+	}
+	deps[path] = struct{}{}
+
+	m.mu.Lock()
+	children := make([]string, 0, len(m.dependencies[path]))
+	for im := range m.dependencies[path] {
+		children = append(children, im)
+	}
+	m.mu.Unlock()
+
+	for _, im := range children {
+		m.addDeps(deps, im, keep)
+	}
+}
+
+func (m *DependencyManager) CoundDepsRecursive(path string, keep func(string) bool) int {
+	return len(m.ReachableSet(path, keep))
+}
+
+// ReachableSet returns every package reachable from path (including path
+// itself), restricted to those for which keep returns true.
+func (m *DependencyManager) ReachableSet(path string, keep func(string) bool) map[string]struct{} {
+	deps := make(map[string]struct{})
+	m.addDeps(deps, path, keep)
+	return deps
+}
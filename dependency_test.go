@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+// stubLoader is a Loader backed by an in-memory map, keyed by import path,
+// for use in tests that don't need a real build environment.
+type stubLoader struct {
+	results map[string]*LoadResult
+	errs    map[string]error
+}
+
+func (l *stubLoader) Load(path string, srcDir string) (*LoadResult, error) {
+	if err, ok := l.errs[path]; ok {
+		return nil, err
+	}
+	lr, ok := l.results[path]
+	if !ok {
+		return nil, fmt.Errorf("stubLoader: no entry for %q", path)
+	}
+	return lr, nil
+}
+
+func TestDependencyManagerRecordsLoadErrors(t *testing.T) {
+	loader := &stubLoader{
+		results: map[string]*LoadResult{
+			"a": {ImportPath: "a", PackagePath: "a", Dir: "/a", Imports: []string{"b", "missing"}},
+			"b": {ImportPath: "b", PackagePath: "b", Dir: "/b"},
+		},
+		errs: map[string]error{
+			"missing": fmt.Errorf("no required module provides package missing"),
+		},
+	}
+
+	dm := NewDependencyManager(loader, 1)
+	pkg, deps, err := dm.Get("a", "")
+	if err != nil {
+		t.Fatalf("Get(a): unexpected error: %v", err)
+	}
+	if pkg.PackagePath != "a" {
+		t.Fatalf("Get(a): got PackagePath %q, want %q", pkg.PackagePath, "a")
+	}
+	if len(deps) != 1 || deps[0] != "b" {
+		t.Fatalf("Get(a): deps = %v, want [b]", deps)
+	}
+
+	if len(dm.LoadErrors) != 1 {
+		t.Fatalf("LoadErrors = %v, want exactly one entry", dm.LoadErrors)
+	}
+	if dm.LoadErrors[0].ImportPath != "missing" {
+		t.Fatalf("LoadErrors[0].ImportPath = %q, want %q", dm.LoadErrors[0].ImportPath, "missing")
+	}
+}
+
+func TestDependencyManagerDedupsVendoredImports(t *testing.T) {
+	loader := &stubLoader{
+		results: map[string]*LoadResult{
+			"a":                      {ImportPath: "a", PackagePath: "a", Dir: "/a", Imports: []string{"vendor/example.com/foo"}},
+			"vendor/example.com/foo": {ImportPath: "vendor/example.com/foo", PackagePath: "example.com/foo", Dir: "/a/vendor/example.com/foo"},
+		},
+	}
+
+	dm := NewDependencyManager(loader, 1)
+	pkg, deps, err := dm.Get("a", "")
+	if err != nil {
+		t.Fatalf("Get(a): unexpected error: %v", err)
+	}
+	if got := pkg.Imports["vendor/example.com/foo"]; got != "example.com/foo" {
+		t.Fatalf("Imports[vendor/example.com/foo] = %q, want %q", got, "example.com/foo")
+	}
+	if len(deps) != 1 || deps[0] != "example.com/foo" {
+		t.Fatalf("Get(a): deps = %v, want [example.com/foo]", deps)
+	}
+}
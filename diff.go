@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// PackageCountChange records that a package's transitive dependency count
+// changed between two graphs.
+type PackageCountChange struct {
+	PackagePath string
+	Before      int
+	After       int
+}
+
+// Diff is the result of comparing two dependency graphs.
+type Diff struct {
+	// Added holds packages reachable in b but not in a.
+	Added []string
+	// Removed holds packages reachable in a but not in b.
+	Removed []string
+	// Changed holds packages reachable in both, whose transitive
+	// dependency count differs between a and b.
+	Changed []PackageCountChange
+}
+
+// GraphDiff compares the packages reachable from rootA in a against the
+// packages reachable from rootB in b, restricted to those for which filter
+// returns true. a and b are typically built from isolated Loaders (e.g.
+// different Dir values), letting rootA and rootB name the same import path
+// in two different checkouts, or two different import paths in the same
+// checkout.
+func GraphDiff(a *DependencyManager, rootA string, b *DependencyManager, rootB string, filter func(string) bool) Diff {
+	setA := a.ReachableSet(rootA, filter)
+	setB := b.ReachableSet(rootB, filter)
+
+	var diff Diff
+	for p := range setB {
+		if _, ok := setA[p]; !ok {
+			diff.Added = append(diff.Added, p)
+		}
+	}
+	for p := range setA {
+		if _, ok := setB[p]; !ok {
+			diff.Removed = append(diff.Removed, p)
+		}
+	}
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+
+	for p := range setA {
+		if _, ok := setB[p]; !ok {
+			continue
+		}
+		before := a.CoundDepsRecursive(p, filter)
+		after := b.CoundDepsRecursive(p, filter)
+		if before != after {
+			diff.Changed = append(diff.Changed, PackageCountChange{PackagePath: p, Before: before, After: after})
+		}
+	}
+	sort.Slice(diff.Changed, func(i, j int) bool {
+		return diff.Changed[i].PackagePath < diff.Changed[j].PackagePath
+	})
+
+	return diff
+}
+
+// PrintDiff writes d in a plain diff-like format: "+" for added packages,
+// "-" for removed ones, and "~" for packages whose transitive dependency
+// count changed.
+func PrintDiff(w io.Writer, d Diff) {
+	for _, p := range d.Added {
+		fmt.Fprintf(w, "+ %s\n", p)
+	}
+	for _, p := range d.Removed {
+		fmt.Fprintf(w, "- %s\n", p)
+	}
+	for _, c := range d.Changed {
+		fmt.Fprintf(w, "~ %s (%d -> %d, %+d)\n", c.PackagePath, c.Before, c.After, c.After-c.Before)
+	}
+}
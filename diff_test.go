@@ -0,0 +1,49 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGraphDiff(t *testing.T) {
+	a := newTestDM(t, map[string]*LoadResult{
+		"example.com/app": {ImportPath: "example.com/app", PackagePath: "example.com/app", Dir: "/app", Imports: []string{"example.com/lib", "example.com/old"}},
+		"example.com/lib": {ImportPath: "example.com/lib", PackagePath: "example.com/lib", Dir: "/lib"},
+		"example.com/old": {ImportPath: "example.com/old", PackagePath: "example.com/old", Dir: "/old"},
+	}, "example.com/app")
+
+	b := newTestDM(t, map[string]*LoadResult{
+		"example.com/app": {ImportPath: "example.com/app", PackagePath: "example.com/app", Dir: "/app", Imports: []string{"example.com/lib", "example.com/new"}},
+		"example.com/lib": {ImportPath: "example.com/lib", PackagePath: "example.com/lib", Dir: "/lib", Imports: []string{"example.com/new"}},
+		"example.com/new": {ImportPath: "example.com/new", PackagePath: "example.com/new", Dir: "/new"},
+	}, "example.com/app")
+
+	diff := GraphDiff(a, "example.com/app", b, "example.com/app", allowAll)
+
+	if !reflect.DeepEqual(diff.Added, []string{"example.com/new"}) {
+		t.Errorf("Added = %v, want [example.com/new]", diff.Added)
+	}
+	if !reflect.DeepEqual(diff.Removed, []string{"example.com/old"}) {
+		t.Errorf("Removed = %v, want [example.com/old]", diff.Removed)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0].PackagePath != "example.com/lib" {
+		t.Errorf("Changed = %v, want a single entry for example.com/lib", diff.Changed)
+	}
+}
+
+func TestGraphDiffAppliesFilter(t *testing.T) {
+	a := newTestDM(t, testGraph(), "example.com/app")
+	b := newTestDM(t, map[string]*LoadResult{
+		"example.com/app": {ImportPath: "example.com/app", PackagePath: "example.com/app", Dir: "/app", Imports: []string{"fmt"}},
+		"fmt":             {ImportPath: "fmt", PackagePath: "fmt", Dir: "/usr/go/src/fmt"},
+	}, "example.com/app")
+
+	diff := GraphDiff(a, "example.com/app", b, "example.com/app", excludeStandardFilter)
+
+	if len(diff.Added) != 0 {
+		t.Errorf("Added = %v, want none: -exclude-standard should drop fmt/os from both sides", diff.Added)
+	}
+	if !reflect.DeepEqual(diff.Removed, []string{"example.com/lib"}) {
+		t.Errorf("Removed = %v, want [example.com/lib]", diff.Removed)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+type jsonPackage struct {
+	ImportPath      string   `json:"importPath"`
+	Standard        bool     `json:"standard"`
+	Imports         []string `json:"imports"`
+	TransitiveCount int      `json:"transitiveCount"`
+	Dir             string   `json:"dir"`
+}
+
+type jsonGraph struct {
+	Root     string        `json:"root"`
+	Packages []jsonPackage `json:"packages"`
+}
+
+// graphPackages returns the packages of dm that pass filter, sorted by
+// PackagePath, along with their deduplicated, sorted direct imports.
+func graphPackages(dm *DependencyManager, filter func(string) bool) []*Package {
+	pkgs := dm.AllPackages()
+	kept := pkgs[:0]
+	for _, pkg := range pkgs {
+		if filter(pkg.PackagePath) {
+			kept = append(kept, pkg)
+		}
+	}
+	sort.Slice(kept, func(i, j int) bool {
+		return kept[i].PackagePath < kept[j].PackagePath
+	})
+	return kept
+}
+
+// sortedImports returns pkg's deduplicated, sorted direct imports, dropping
+// any that filter rejects.
+func sortedImports(pkg *Package, filter func(string) bool) []string {
+	seen := make(map[string]bool, len(pkg.Imports))
+	imports := make([]string, 0, len(pkg.Imports))
+	for _, packagePath := range pkg.Imports {
+		if !filter(packagePath) {
+			continue
+		}
+		if !seen[packagePath] {
+			seen[packagePath] = true
+			imports = append(imports, packagePath)
+		}
+	}
+	sort.Strings(imports)
+	return imports
+}
+
+// WriteJSON writes the dependency graph resolved by dm as JSON.
+func WriteJSON(w io.Writer, dm *DependencyManager, root string, filter func(string) bool) error {
+	graph := jsonGraph{Root: root}
+	for _, pkg := range graphPackages(dm, filter) {
+		graph.Packages = append(graph.Packages, jsonPackage{
+			ImportPath:      pkg.PackagePath,
+			Standard:        pkg.Standard,
+			Imports:         sortedImports(pkg, filter),
+			TransitiveCount: dm.CoundDepsRecursive(pkg.PackagePath, filter),
+			Dir:             pkg.Dir,
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(graph)
+}
+
+// WriteDOT writes the dependency graph resolved by dm as a Graphviz digraph,
+// styling standard-library packages differently from the rest.
+func WriteDOT(w io.Writer, dm *DependencyManager, filter func(string) bool) error {
+	fmt.Fprintln(w, "digraph deps {")
+	fmt.Fprintln(w, "\tnode [shape=box];")
+
+	for _, pkg := range graphPackages(dm, filter) {
+		if pkg.Standard {
+			fmt.Fprintf(w, "\t%s [style=filled,fillcolor=lightgray];\n", strconv.Quote(pkg.PackagePath))
+		}
+		for _, im := range sortedImports(pkg, filter) {
+			fmt.Fprintf(w, "\t%s -> %s;\n", strconv.Quote(pkg.PackagePath), strconv.Quote(im))
+		}
+	}
+
+	fmt.Fprintln(w, "}")
+	return nil
+}
+
+// WriteCSV writes the dependency graph resolved by dm as "from,to" edges.
+func WriteCSV(w io.Writer, dm *DependencyManager, filter func(string) bool) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"from", "to"}); err != nil {
+		return err
+	}
+
+	for _, pkg := range graphPackages(dm, filter) {
+		for _, im := range sortedImports(pkg, filter) {
+			if err := cw.Write([]string{pkg.PackagePath, im}); err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
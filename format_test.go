@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// newTestDM builds a DependencyManager over a stubLoader graph and resolves
+// root, so callers can exercise the output formats against it.
+func newTestDM(t *testing.T, results map[string]*LoadResult, root string) *DependencyManager {
+	t.Helper()
+	dm := NewDependencyManager(&stubLoader{results: results}, 1)
+	if _, _, err := dm.Get(root, ""); err != nil {
+		t.Fatalf("Get(%q): %v", root, err)
+	}
+	return dm
+}
+
+func excludeStandardFilter(importPath string) bool {
+	return !IsStandardPackage(importPath)
+}
+
+func allowAll(string) bool { return true }
+
+func testGraph() map[string]*LoadResult {
+	return map[string]*LoadResult{
+		"example.com/app": {ImportPath: "example.com/app", PackagePath: "example.com/app", Dir: "/app", Imports: []string{"example.com/lib", "fmt"}},
+		"example.com/lib": {ImportPath: "example.com/lib", PackagePath: "example.com/lib", Dir: "/lib", Imports: []string{"os"}},
+		"fmt":             {ImportPath: "fmt", PackagePath: "fmt", Dir: "/usr/go/src/fmt"},
+		"os":              {ImportPath: "os", PackagePath: "os", Dir: "/usr/go/src/os"},
+	}
+}
+
+func TestWriteJSONAppliesFilter(t *testing.T) {
+	dm := newTestDM(t, testGraph(), "example.com/app")
+
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, dm, "example.com/app", excludeStandardFilter); err != nil {
+		t.Fatalf("WriteJSON: %v", err)
+	}
+	out := buf.String()
+
+	if strings.Contains(out, `"fmt"`) || strings.Contains(out, `"os"`) {
+		t.Errorf("WriteJSON output still mentions standard packages with -exclude-standard:\n%s", out)
+	}
+	if !strings.Contains(out, `"example.com/lib"`) {
+		t.Errorf("WriteJSON output missing example.com/lib:\n%s", out)
+	}
+}
+
+func TestWriteDOTAppliesFilter(t *testing.T) {
+	dm := newTestDM(t, testGraph(), "example.com/app")
+
+	var buf bytes.Buffer
+	if err := WriteDOT(&buf, dm, excludeStandardFilter); err != nil {
+		t.Fatalf("WriteDOT: %v", err)
+	}
+	out := buf.String()
+
+	if strings.Contains(out, `"fmt"`) || strings.Contains(out, `"os"`) {
+		t.Errorf("WriteDOT output still mentions standard packages with -exclude-standard:\n%s", out)
+	}
+	if !strings.Contains(out, `"example.com/app" -> "example.com/lib"`) {
+		t.Errorf("WriteDOT output missing app -> lib edge:\n%s", out)
+	}
+}
+
+func TestWriteCSVAppliesFilter(t *testing.T) {
+	dm := newTestDM(t, testGraph(), "example.com/app")
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, dm, excludeStandardFilter); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+	out := buf.String()
+
+	if strings.Contains(out, "fmt") || strings.Contains(out, ",os") {
+		t.Errorf("WriteCSV output still mentions standard packages with -exclude-standard:\n%s", out)
+	}
+	if !strings.Contains(out, "example.com/app,example.com/lib") {
+		t.Errorf("WriteCSV output missing app,lib edge:\n%s", out)
+	}
+}
+
+func TestWriteJSONUnfilteredIncludesStandard(t *testing.T) {
+	dm := newTestDM(t, testGraph(), "example.com/app")
+
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, dm, "example.com/app", allowAll); err != nil {
+		t.Fatalf("WriteJSON: %v", err)
+	}
+	out := buf.String()
+
+	if !strings.Contains(out, `"fmt"`) || !strings.Contains(out, `"os"`) {
+		t.Errorf("WriteJSON output missing standard packages without a filter:\n%s", out)
+	}
+}
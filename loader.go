@@ -0,0 +1,24 @@
+package main
+
+// LoadResult is the normalized result of resolving a single import path,
+// as produced by a Loader.
+type LoadResult struct {
+	// ImportPath is the path as resolved from the requesting srcDir; for
+	// GOPATH-style vendoring this may include a "vendor/" prefix.
+	ImportPath string
+	// PackagePath is the canonical, vendor-stripped path for this package.
+	PackagePath string
+	// Dir is the package's directory on disk, used as the srcDir for
+	// resolving its own imports.
+	Dir string
+	// Imports lists the package's direct imports, exactly as written in
+	// its source files.
+	Imports []string
+}
+
+// Loader resolves a single import path, relative to srcDir, into a Package.
+// Implementations may use go/build (GOPATH semantics) or
+// golang.org/x/tools/go/packages (module- and vendor-aware semantics).
+type Loader interface {
+	Load(path string, srcDir string) (*LoadResult, error)
+}
@@ -0,0 +1,32 @@
+package main
+
+import "go/build"
+
+// BuildLoader resolves import paths with go/build, i.e. plain GOPATH
+// semantics: no module awareness, no replace directives, and vendor
+// resolution only as implemented by go/build itself.
+type BuildLoader struct {
+	Context *build.Context
+}
+
+// NewBuildLoader returns a BuildLoader using ctx, or build.Default if ctx is nil.
+func NewBuildLoader(ctx *build.Context) *BuildLoader {
+	if ctx == nil {
+		ctx = &build.Default
+	}
+	return &BuildLoader{Context: ctx}
+}
+
+func (l *BuildLoader) Load(path string, srcDir string) (*LoadResult, error) {
+	pkg, err := l.Context.Import(path, srcDir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LoadResult{
+		ImportPath:  pkg.ImportPath,
+		PackagePath: CanonicalPackagePath(pkg.ImportPath),
+		Dir:         pkg.Dir,
+		Imports:     pkg.Imports,
+	}, nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// packagesLoadMode is the set of go/packages.NeedXxx bits we require: enough
+// to resolve a package's identity, its directory, and its direct imports,
+// plus NeedDeps so that a single driver invocation returns the whole
+// transitive closure rather than one level at a time.
+const packagesLoadMode = packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps
+
+// PackagesLoader resolves import paths with golang.org/x/tools/go/packages,
+// i.e. module-aware semantics: it understands go.mod, replace directives,
+// and module vendor consolidation, and is driven under the hood by
+// `go list -json -deps -e`.
+type PackagesLoader struct {
+	// Env and BuildFlags are passed through to the underlying "go list"
+	// invocation, letting callers target a GOOS/GOARCH/build-tag/cgo
+	// combination other than the host's.
+	Env        []string
+	BuildFlags []string
+
+	mu    sync.Mutex
+	nodes map[string]*packages.Package // PkgPath -> resolved node
+}
+
+// NewPackagesLoader returns a Loader backed by golang.org/x/tools/go/packages,
+// targeting platform.
+func NewPackagesLoader(platform PlatformConfig) *PackagesLoader {
+	return &PackagesLoader{
+		Env:        platform.Env(),
+		BuildFlags: platform.BuildFlags(),
+		nodes:      make(map[string]*packages.Package),
+	}
+}
+
+func (l *PackagesLoader) Load(path string, srcDir string) (*LoadResult, error) {
+	if pkg, ok := l.lookup(path); ok {
+		if len(pkg.Errors) > 0 {
+			return nil, pkg.Errors[0]
+		}
+		return loadResultFromPackage(pkg), nil
+	}
+
+	cfg := &packages.Config{
+		Mode:       packagesLoadMode,
+		Dir:        srcDir,
+		Env:        l.Env,
+		BuildFlags: l.BuildFlags,
+	}
+	pkgs, err := packages.Load(cfg, path)
+	if err != nil {
+		return nil, fmt.Errorf("packages.Load %q: %w", path, err)
+	}
+	if len(pkgs) == 0 {
+		return nil, fmt.Errorf("package %q not found", path)
+	}
+
+	root := pkgs[0]
+	l.store(root)
+	if len(root.Errors) > 0 {
+		return nil, root.Errors[0]
+	}
+
+	return loadResultFromPackage(root), nil
+}
+
+func (l *PackagesLoader) lookup(pkgPath string) (*packages.Package, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	pkg, ok := l.nodes[pkgPath]
+	return pkg, ok
+}
+
+// store records pkg and, since it was loaded with NeedDeps, its entire
+// transitive import graph, so that later lookups for any package reachable
+// from pkg are served from memory instead of invoking the driver again.
+func (l *PackagesLoader) store(pkg *packages.Package) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.storeLocked(pkg, make(map[string]bool))
+}
+
+func (l *PackagesLoader) storeLocked(pkg *packages.Package, visited map[string]bool) {
+	if visited[pkg.PkgPath] {
+		return
+	}
+	visited[pkg.PkgPath] = true
+	l.nodes[pkg.PkgPath] = pkg
+	for _, im := range pkg.Imports {
+		l.storeLocked(im, visited)
+	}
+}
+
+func loadResultFromPackage(pkg *packages.Package) *LoadResult {
+	imports := make([]string, 0, len(pkg.Imports))
+	for im := range pkg.Imports {
+		imports = append(imports, im)
+	}
+
+	var dir string
+	if len(pkg.GoFiles) > 0 {
+		dir = filepath.Dir(pkg.GoFiles[0])
+	}
+
+	return &LoadResult{
+		ImportPath:  pkg.PkgPath,
+		PackagePath: CanonicalPackagePath(pkg.PkgPath),
+		Dir:         dir,
+		Imports:     imports,
+	}
+}
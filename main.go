@@ -6,138 +6,48 @@ import (
 	"go/build"
 	"log"
 	"os"
+	"runtime"
 	"sort"
 	"strings"
-	"sync"
 )
 
-func IsStandardPackage(importPath string) bool {
-	x := strings.SplitN(importPath, "/", 2)
-	return !strings.Contains(x[0], ".")
-}
-
-type PackageCache struct {
-	mu       sync.Mutex
-	packages map[string]*build.Package
-}
-
-func NewPackageCache() *PackageCache {
-	return &PackageCache{
-		packages: make(map[string]*build.Package),
-	}
-}
-
-func (c *PackageCache) Get(importPath string) (*build.Package, bool) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	pkg, ok := c.packages[importPath]
-	return pkg, ok
-}
-
-func (c *PackageCache) Put(pkg *build.Package) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.packages[pkg.ImportPath] = pkg
-}
-
-type DependencyManager struct {
-	packages *PackageCache
-
-	mu           sync.Mutex
-	dependencies map[string]map[string]struct{}
-}
-
-func NewDependencyManager() *DependencyManager {
-	return &DependencyManager{
-		packages:     NewPackageCache(),
-		dependencies: make(map[string]map[string]struct{}),
-	}
-}
-
-func (m *DependencyManager) Import(path string, srcDir string) (*build.Package, error) {
-	pkg, err := build.Import(path, srcDir, 0)
-	if err != nil {
-		return nil, err
-	}
-
-	cachedPkg, ok := m.packages.Get(pkg.ImportPath)
-	if ok {
-		return cachedPkg, nil
-	}
+var (
+	excludeStandard = flag.Bool("exclude-standard", false, "exclude standard packages")
+	loaderName      = flag.String("loader", "packages", "package loader to use: \"packages\" (module- and vendor-aware) or \"build\" (plain GOPATH, for legacy projects)")
+	jobs            = flag.Int("j", 0, "number of packages to load concurrently (default: GOMAXPROCS)")
 
-	m.packages.Put(pkg)
+	goos   = flag.String("goos", runtime.GOOS, "target GOOS")
+	goarch = flag.String("goarch", runtime.GOARCH, "target GOARCH")
+	tags   = flag.String("tags", "", "comma-separated build tags")
+	cgo    = flag.Bool("cgo", build.Default.CgoEnabled, "enable cgo")
+	matrix = flag.String("matrix", "", "comma-separated goos/goarch pairs (e.g. linux/amd64,js/wasm); loads the graph once per platform and prints the union, annotating each edge with the platforms that pull it in")
+	format = flag.String("format", "text", "output format: \"text\", \"json\", \"dot\", or \"csv\"")
 
-	for _, im := range pkg.Imports {
-		if im == "C" {
-			continue
-		}
+	why    = flag.String("why", "", "print the shortest import chain(s) from the root package to this import path")
+	whyAll = flag.Bool("why-all", false, "with -why, print every shortest chain instead of just one")
 
-		importedPkg, err := m.Import(im, pkg.Dir)
-		if err != nil {
-			return nil, err
-		}
+	diffImportPath = flag.String("diff", "", "compare the dependency graph against this other import path, loaded from the same directory")
+	diffDir        = flag.String("diff-dir", "", "compare the dependency graph of the same import path as loaded from this other directory (e.g. a checkout of a different revision)")
+)
 
-		m.mu.Lock()
-		if m.dependencies[pkg.ImportPath] == nil {
-			m.dependencies[pkg.ImportPath] = make(map[string]struct{})
-		}
-		m.dependencies[pkg.ImportPath][importedPkg.ImportPath] = struct{}{}
-		m.mu.Unlock()
+func splitTags(s string) []string {
+	if s == "" {
+		return nil
 	}
-
-	return pkg, nil
+	return strings.Split(s, ",")
 }
 
-func (m *DependencyManager) Get(path string, srcDir string) (*build.Package, []string, error) {
-	findPkg, err := build.Import(path, srcDir, build.FindOnly)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	pkg, ok := m.packages.Get(findPkg.ImportPath)
-	if !ok {
-		pkg, err = m.Import(path, srcDir)
-		if err != nil {
-			return nil, nil, err
-		}
-	}
-
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	depsMap := m.dependencies[pkg.ImportPath]
-	var deps []string
-	if len(depsMap) > 0 {
-		deps = make([]string, 0, len(depsMap))
-		for im := range depsMap {
-			deps = append(deps, im)
-		}
+func newLoader(name string, platform PlatformConfig) (Loader, error) {
+	switch name {
+	case "packages":
+		return NewPackagesLoader(platform), nil
+	case "build":
+		return NewBuildLoader(platform.BuildContext()), nil
+	default:
+		return nil, fmt.Errorf("unknown -loader %q: must be \"packages\" or \"build\"", name)
 	}
-
-	return pkg, deps, nil
 }
 
-func (m *DependencyManager) addDeps(deps map[string]struct{}, path string, keep func(string) bool) {
-	if !keep(path) {
-		return
-	}
-	if _, ok := deps[path]; ok {
-		return
-	}
-	deps[path] = struct{}{}
-	for im := range m.dependencies[path] {
-		m.addDeps(deps, im, keep)
-	}
-}
-
-func (m *DependencyManager) CoundDepsRecursive(path string, keep func(string) bool) int {
-	deps := make(map[string]struct{})
-	m.addDeps(deps, path, keep)
-	return len(deps)
-}
-
-var excludeStandard = flag.Bool("exclude-standard", false, "exclude standard packages")
-
 func main() {
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "usage: %s <importPath>\n", os.Args[0])
@@ -164,18 +74,107 @@ func main() {
 		log.Fatal(err)
 	}
 
-	dm := NewDependencyManager()
-	_, deps, err := dm.Get(flag.Args()[0], cwd)
+	defaultPlatform := PlatformConfig{GOOS: *goos, GOARCH: *goarch, Tags: splitTags(*tags), CgoEnabled: *cgo}
+
+	if *matrix != "" {
+		var platforms []PlatformConfig
+		for _, s := range strings.Split(*matrix, ",") {
+			p, err := ParsePlatform(s, defaultPlatform)
+			if err != nil {
+				log.Fatal(err)
+			}
+			platforms = append(platforms, p)
+		}
+		if err := RunMatrix(*loaderName, platforms, flag.Args()[0], cwd, *jobs, filter); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	loader, err := newLoader(*loaderName, defaultPlatform)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	dm := NewDependencyManager(loader, *jobs)
+	root := flag.Args()[0]
+	rootPkg, deps, err := dm.Get(root, cwd)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	sort.Strings(deps)
+	if *diffImportPath != "" || *diffDir != "" {
+		if *diffImportPath != "" && *diffDir != "" {
+			log.Fatal("-diff and -diff-dir are mutually exclusive")
+		}
+
+		otherLoader, err := newLoader(*loaderName, defaultPlatform)
+		if err != nil {
+			log.Fatal(err)
+		}
+		otherDM := NewDependencyManager(otherLoader, *jobs)
+
+		otherRoot, otherDir := root, *diffDir
+		if *diffImportPath != "" {
+			otherRoot, otherDir = *diffImportPath, cwd
+		}
+
+		otherRootPkg, _, err := otherDM.Get(otherRoot, otherDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		diff := GraphDiff(dm, rootPkg.PackagePath, otherDM, otherRootPkg.PackagePath, filter)
+		PrintDiff(os.Stdout, diff)
+		return
+	}
+
+	if *why != "" {
+		target := CanonicalPackagePath(*why)
+		if *whyAll {
+			paths := AllShortestPaths(dm, rootPkg.PackagePath, target)
+			if len(paths) == 0 {
+				log.Fatalf("%s does not import %s", rootPkg.PackagePath, target)
+			}
+			for _, path := range paths {
+				fmt.Println(strings.Join(path, "\n\t-> "))
+			}
+		} else {
+			path := ShortestPath(dm, rootPkg.PackagePath, target)
+			if path == nil {
+				log.Fatalf("%s does not import %s", rootPkg.PackagePath, target)
+			}
+			fmt.Println(strings.Join(path, "\n\t-> "))
+		}
+		return
+	}
+
+	switch *format {
+	case "text":
+		sort.Strings(deps)
+		for _, im := range deps {
+			if !filter(im) {
+				continue
+			}
+			fmt.Printf("%6d %s\n", dm.CoundDepsRecursive(im, filter), im)
+		}
+	case "json":
+		err = WriteJSON(os.Stdout, dm, root, filter)
+	case "dot":
+		err = WriteDOT(os.Stdout, dm, filter)
+	case "csv":
+		err = WriteCSV(os.Stdout, dm, filter)
+	default:
+		err = fmt.Errorf("unknown -format %q: must be \"text\", \"json\", \"dot\", or \"csv\"", *format)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	for _, im := range deps {
-		if !filter(im) {
-			continue
+	if len(dm.LoadErrors) > 0 {
+		fmt.Fprintf(os.Stderr, "\n%d package(s) failed to load:\n", len(dm.LoadErrors))
+		for _, loadErr := range dm.LoadErrors {
+			fmt.Fprintf(os.Stderr, "  %s\n", loadErr.Error())
 		}
-		fmt.Printf("%6d %s\n", dm.CoundDepsRecursive(im, filter), im)
 	}
 }
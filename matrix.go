@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type edge struct {
+	from, to string
+}
+
+// RunMatrix loads the dependency graph of rootPath once per platform and
+// prints the union of all edges seen across platforms, each annotated with
+// the set of platforms that pull it in. This makes conditionally-imported
+// dependencies (e.g. syscall/js only on js/wasm) easy to spot. Edges whose
+// endpoints filter rejects are omitted, same as the other output modes.
+func RunMatrix(loaderName string, platforms []PlatformConfig, rootPath string, srcDir string, jobs int, filter func(string) bool) error {
+	platformsByEdge := make(map[edge]map[string]bool)
+
+	for _, platform := range platforms {
+		loader, err := newLoader(loaderName, platform)
+		if err != nil {
+			return err
+		}
+
+		dm := NewDependencyManager(loader, jobs)
+		if _, _, err := dm.Get(rootPath, srcDir); err != nil {
+			return fmt.Errorf("%s: %w", platform, err)
+		}
+
+		for from, tos := range dm.dependencies {
+			if !filter(from) {
+				continue
+			}
+			for to := range tos {
+				if !filter(to) {
+					continue
+				}
+				e := edge{from, to}
+				if platformsByEdge[e] == nil {
+					platformsByEdge[e] = make(map[string]bool)
+				}
+				platformsByEdge[e][platform.String()] = true
+			}
+		}
+	}
+
+	edges := make([]edge, 0, len(platformsByEdge))
+	for e := range platformsByEdge {
+		edges = append(edges, e)
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].from != edges[j].from {
+			return edges[i].from < edges[j].from
+		}
+		return edges[i].to < edges[j].to
+	})
+
+	for _, e := range edges {
+		plats := make([]string, 0, len(platformsByEdge[e]))
+		for p := range platformsByEdge[e] {
+			plats = append(plats, p)
+		}
+		sort.Strings(plats)
+		fmt.Printf("%s -> %s [%s]\n", e.from, e.to, strings.Join(plats, ","))
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"go/build"
+	"os"
+	"strings"
+)
+
+// PlatformConfig selects the GOOS/GOARCH/build-tag/cgo matrix cell that a
+// Loader should resolve imports for.
+type PlatformConfig struct {
+	GOOS       string
+	GOARCH     string
+	Tags       []string
+	CgoEnabled bool
+}
+
+// String returns the "goos/goarch" form used to label matrix output.
+func (p PlatformConfig) String() string {
+	return p.GOOS + "/" + p.GOARCH
+}
+
+// ParsePlatform parses a single "goos/goarch" matrix entry, using
+// defaultPlatform's GOOS/GOARCH as a fallback for an omitted side (e.g.
+// "js/" or "/386").
+func ParsePlatform(s string, defaultPlatform PlatformConfig) (PlatformConfig, error) {
+	goos, goarch, ok := strings.Cut(s, "/")
+	if !ok {
+		return PlatformConfig{}, fmt.Errorf("invalid platform %q: want goos/goarch", s)
+	}
+	p := defaultPlatform
+	if goos != "" {
+		p.GOOS = goos
+	}
+	if goarch != "" {
+		p.GOARCH = goarch
+	}
+	return p, nil
+}
+
+// BuildContext returns a go/build.Context configured for p, for use with a
+// BuildLoader.
+func (p PlatformConfig) BuildContext() *build.Context {
+	ctx := build.Default
+	ctx.GOOS = p.GOOS
+	ctx.GOARCH = p.GOARCH
+	ctx.CgoEnabled = p.CgoEnabled
+	if len(p.Tags) > 0 {
+		ctx.BuildTags = p.Tags
+	}
+	return &ctx
+}
+
+// Env returns the environment overrides and "go list"-compatible
+// BuildFlags for p, for use with a PackagesLoader.
+func (p PlatformConfig) Env() []string {
+	cgo := "0"
+	if p.CgoEnabled {
+		cgo = "1"
+	}
+	return append(os.Environ(), "GOOS="+p.GOOS, "GOARCH="+p.GOARCH, "CGO_ENABLED="+cgo)
+}
+
+func (p PlatformConfig) BuildFlags() []string {
+	if len(p.Tags) == 0 {
+		return nil
+	}
+	return []string{"-tags=" + strings.Join(p.Tags, ",")}
+}
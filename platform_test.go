@@ -0,0 +1,35 @@
+package main
+
+import "testing"
+
+func TestParsePlatform(t *testing.T) {
+	def := PlatformConfig{GOOS: "linux", GOARCH: "amd64"}
+
+	tests := []struct {
+		in      string
+		want    PlatformConfig
+		wantErr bool
+	}{
+		{in: "js/wasm", want: PlatformConfig{GOOS: "js", GOARCH: "wasm"}},
+		{in: "js/", want: PlatformConfig{GOOS: "js", GOARCH: "amd64"}},
+		{in: "/386", want: PlatformConfig{GOOS: "linux", GOARCH: "386"}},
+		{in: "linux", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParsePlatform(tt.in, def)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParsePlatform(%q) = %v, want error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParsePlatform(%q): unexpected error: %v", tt.in, err)
+			continue
+		}
+		if got.GOOS != tt.want.GOOS || got.GOARCH != tt.want.GOARCH {
+			t.Errorf("ParsePlatform(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import "sort"
+
+// ShortestPath returns one shortest chain of imports from root to target
+// (inclusive of both ends), found via BFS over dm's dependency graph.
+// It returns nil if target is not reachable from root.
+func ShortestPath(dm *DependencyManager, root, target string) []string {
+	if root == target {
+		return []string{root}
+	}
+
+	parent := make(map[string]string)
+	visited := map[string]bool{root: true}
+	queue := []string{root}
+
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+
+		for _, next := range dm.Neighbors(cur) {
+			if visited[next] {
+				continue
+			}
+			visited[next] = true
+			parent[next] = cur
+			if next == target {
+				return buildPath(parent, root, target)
+			}
+			queue = append(queue, next)
+		}
+	}
+
+	return nil
+}
+
+// AllShortestPaths returns every simple path from root to target whose
+// length equals the shortest distance between them. It works by running a
+// level-by-level BFS that records, for each node, the set of predecessors
+// that reach it at the shortest distance, then walking that predecessor
+// DAG backwards from target.
+func AllShortestPaths(dm *DependencyManager, root, target string) [][]string {
+	if root == target {
+		return [][]string{{root}}
+	}
+
+	dist := map[string]int{root: 0}
+	predecessors := make(map[string][]string)
+
+	level := []string{root}
+	for len(level) > 0 {
+		if contains(level, target) {
+			break
+		}
+
+		nextSet := make(map[string]bool)
+		for _, cur := range level {
+			for _, next := range dm.Neighbors(cur) {
+				d, known := dist[next]
+				switch {
+				case !known:
+					dist[next] = dist[cur] + 1
+					predecessors[next] = append(predecessors[next], cur)
+					nextSet[next] = true
+				case d == dist[cur]+1:
+					predecessors[next] = append(predecessors[next], cur)
+				}
+			}
+		}
+
+		level = make([]string, 0, len(nextSet))
+		for n := range nextSet {
+			level = append(level, n)
+		}
+		sort.Strings(level)
+	}
+
+	if _, ok := dist[target]; !ok {
+		return nil
+	}
+
+	var paths [][]string
+	var walk func(node string, suffix []string)
+	walk = func(node string, suffix []string) {
+		path := append([]string{node}, suffix...)
+		if node == root {
+			paths = append(paths, path)
+			return
+		}
+		for _, p := range predecessors[node] {
+			walk(p, path)
+		}
+	}
+	walk(target, nil)
+
+	return paths
+}
+
+func buildPath(parent map[string]string, root, node string) []string {
+	path := []string{node}
+	for node != root {
+		node = parent[node]
+		path = append(path, node)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+func contains(haystack []string, needle string) bool {
+	for _, s := range haystack {
+		if s == needle {
+			return true
+		}
+	}
+	return false
+}
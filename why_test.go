@@ -0,0 +1,73 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// newTestDependencyManager builds a DependencyManager whose dependency
+// graph is exactly edges, without going through a Loader.
+func newTestDependencyManager(edges map[string][]string) *DependencyManager {
+	dm := NewDependencyManager(&stubLoader{}, 1)
+	for from, tos := range edges {
+		dm.dependencies[from] = make(map[string]struct{}, len(tos))
+		for _, to := range tos {
+			dm.dependencies[from][to] = struct{}{}
+		}
+	}
+	return dm
+}
+
+func TestShortestPath(t *testing.T) {
+	dm := newTestDependencyManager(map[string][]string{
+		"a": {"b", "c"},
+		"b": {"d"},
+		"c": {"d"},
+	})
+
+	if got := ShortestPath(dm, "a", "a"); !reflect.DeepEqual(got, []string{"a"}) {
+		t.Errorf("ShortestPath(a, a) = %v, want [a]", got)
+	}
+
+	got := ShortestPath(dm, "a", "d")
+	if len(got) != 3 || got[0] != "a" || got[2] != "d" {
+		t.Errorf("ShortestPath(a, d) = %v, want a chain of length 3 from a to d", got)
+	}
+
+	if got := ShortestPath(dm, "a", "missing"); got != nil {
+		t.Errorf("ShortestPath(a, missing) = %v, want nil", got)
+	}
+}
+
+func TestAllShortestPaths(t *testing.T) {
+	dm := newTestDependencyManager(map[string][]string{
+		"a": {"b", "c"},
+		"b": {"d"},
+		"c": {"d"},
+	})
+
+	paths := AllShortestPaths(dm, "a", "d")
+	want := [][]string{{"a", "b", "d"}, {"a", "c", "d"}}
+	sort.Slice(paths, func(i, j int) bool { return paths[i][1] < paths[j][1] })
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("AllShortestPaths(a, d) = %v, want %v", paths, want)
+	}
+
+	if got := AllShortestPaths(dm, "a", "a"); !reflect.DeepEqual(got, [][]string{{"a"}}) {
+		t.Errorf("AllShortestPaths(a, a) = %v, want [[a]]", got)
+	}
+
+	if got := AllShortestPaths(dm, "a", "missing"); got != nil {
+		t.Errorf("AllShortestPaths(a, missing) = %v, want nil", got)
+	}
+}
+
+func TestContains(t *testing.T) {
+	if !contains([]string{"a", "b"}, "b") {
+		t.Error("contains([a b], b) = false, want true")
+	}
+	if contains([]string{"a", "b"}, "c") {
+		t.Error("contains([a b], c) = true, want false")
+	}
+}